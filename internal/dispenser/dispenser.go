@@ -0,0 +1,160 @@
+package dispenser
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SlotDispenser is the subset of repository.SlotRepository a Dispenser needs
+// to actually fire a slot's relays.
+type SlotDispenser interface {
+	Dispense(slotLabel string) error
+}
+
+// Dispenser serializes dispense jobs per slot: each slot gets its own
+// buffered queue and a single worker goroutine, so two orders hitting the
+// same slot concurrently can never drive its relays at the same time. Jobs
+// for different slots run fully in parallel. Every job's lifecycle is
+// persisted to a JobStore, both as an audit log and so RecoverInterruptedJobs
+// can make sense of what a crash left behind.
+type Dispenser struct {
+	slots SlotDispenser
+	store JobStore
+
+	mu     sync.Mutex
+	queues map[string]chan Job // slot label -> its serialized job queue
+}
+
+// NewDispenser wires a Dispenser from its dependencies. Slot worker queues
+// are created lazily on first use, not up front, since the slot set is only
+// known to the caller.
+func NewDispenser(slots SlotDispenser, store JobStore) *Dispenser {
+	return &Dispenser{
+		slots:  slots,
+		store:  store,
+		queues: make(map[string]chan Job),
+	}
+}
+
+// Enqueue records a new pending job for slotLabel and hands it to that
+// slot's worker queue, starting the worker if this is the slot's first job.
+// Returns as soon as the job is durably recorded; the dispense itself
+// happens on the worker goroutine. correlationID is carried on the job
+// purely for log correlation and is otherwise opaque to the Dispenser.
+func (d *Dispenser) Enqueue(orderID, slotLabel, correlationID string) (Job, error) {
+	job := Job{
+		ID:            uuid.NewString(),
+		OrderID:       orderID,
+		Slot:          slotLabel,
+		Status:        StatusPending,
+		CorrelationID: correlationID,
+		CreatedAt:     time.Now(),
+	}
+
+	if err := d.store.Save(job); err != nil {
+		return Job{}, err
+	}
+
+	d.queueFor(slotLabel) <- job
+	return job, nil
+}
+
+// Job looks up a single job's current status.
+func (d *Dispenser) Job(id string) (Job, bool, error) {
+	return d.store.Get(id)
+}
+
+// Jobs lists every job the Dispenser has ever recorded.
+func (d *Dispenser) Jobs() ([]Job, error) {
+	return d.store.List()
+}
+
+// RecoverInterruptedJobs reloads every job from the store at boot. Jobs that
+// were never started are safe to requeue as-is. Jobs caught mid-dispense
+// can't be resumed without risking driving the same relay twice, so they're
+// marked failed and left for an operator or a fresh order to retry.
+func (d *Dispenser) RecoverInterruptedJobs() error {
+	jobs, err := d.store.List()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		switch job.Status {
+		case StatusPending:
+			jobLogger(job).Info("re-queuing pending job after restart")
+			d.queueFor(job.Slot) <- job
+		case StatusRunning:
+			jobLogger(job).Warn("marking job failed, interrupted by restart")
+			job.Status = StatusFailed
+			job.Error = "interrupted by restart"
+			job.EndedAt = time.Now()
+			if err := d.store.Save(job); err != nil {
+				jobLogger(job).Error("failed to persist interrupted job", "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispenser) queueFor(slotLabel string) chan Job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	queue, ok := d.queues[slotLabel]
+	if !ok {
+		queue = make(chan Job, 32)
+		d.queues[slotLabel] = queue
+		go d.worker(queue)
+	}
+	return queue
+}
+
+func (d *Dispenser) worker(queue <-chan Job) {
+	for job := range queue {
+		d.run(job)
+	}
+}
+
+func (d *Dispenser) run(job Job) {
+	job.Status = StatusRunning
+	job.StartedAt = time.Now()
+	if err := d.store.Save(job); err != nil {
+		jobLogger(job).Error("failed to persist running job", "error", err)
+	}
+
+	err := d.slots.Dispense(job.Slot)
+
+	job.EndedAt = time.Now()
+	duration := job.EndedAt.Sub(job.StartedAt)
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		jobLogger(job).Error("job failed dispensing slot", "error", err, "duration_ms", duration.Milliseconds())
+	} else {
+		job.Status = StatusSucceeded
+		jobLogger(job).Info("job finished dispensing slot", "duration_ms", duration.Milliseconds())
+	}
+	recordJobOutcome(job.Slot, job.Status)
+
+	if err := d.store.Save(job); err != nil {
+		jobLogger(job).Error("failed to persist finished job", "error", err)
+	}
+}
+
+// jobLogger returns a structured logger tagged with the fields needed to
+// correlate a dispense job's log lines with the webhook delivery that
+// triggered it.
+func jobLogger(job Job) *slog.Logger {
+	return slog.With(
+		"component", "dispenser",
+		"job_id", job.ID,
+		"order_id", job.OrderID,
+		"slot", job.Slot,
+		"correlation_id", job.CorrelationID,
+	)
+}
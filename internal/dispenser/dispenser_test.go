@@ -0,0 +1,161 @@
+package dispenser
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{jobs: make(map[string]Job)}
+}
+
+func (s *memoryJobStore) Save(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *memoryJobStore) Get(id string) (Job, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	return job, ok, nil
+}
+
+func (s *memoryJobStore) List() ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// slowSlotDispenser records whether any two Dispense calls for the same slot
+// overlapped in time.
+type slowSlotDispenser struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	running map[string]bool
+	overlap bool
+}
+
+func (d *slowSlotDispenser) Dispense(slotLabel string) error {
+	d.mu.Lock()
+	if d.running[slotLabel] {
+		d.overlap = true
+	}
+	d.running[slotLabel] = true
+	d.mu.Unlock()
+
+	time.Sleep(d.delay)
+
+	d.mu.Lock()
+	d.running[slotLabel] = false
+	d.mu.Unlock()
+
+	return nil
+}
+
+func waitForStatus(t *testing.T, store JobStore, id string, want Status) Job {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		job, ok, err := store.Get(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok && job.Status == want {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not reach status %s in time", id, want)
+	return Job{}
+}
+
+func TestDispenserSerializesJobsPerSlot(t *testing.T) {
+	slots := &slowSlotDispenser{delay: 20 * time.Millisecond, running: map[string]bool{}}
+	store := newMemoryJobStore()
+	d := NewDispenser(slots, store)
+
+	jobA, err := d.Enqueue("order-a", "A1", "corr-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jobB, err := d.Enqueue("order-b", "A1", "corr-b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waitForStatus(t, store, jobA.ID, StatusSucceeded)
+	waitForStatus(t, store, jobB.ID, StatusSucceeded)
+
+	if slots.overlap {
+		t.Fatal("expected jobs for the same slot to never run concurrently")
+	}
+}
+
+func TestDispenserRunsDifferentSlotsConcurrently(t *testing.T) {
+	slots := &slowSlotDispenser{delay: 50 * time.Millisecond, running: map[string]bool{}}
+	store := newMemoryJobStore()
+	d := NewDispenser(slots, store)
+
+	start := time.Now()
+	jobA, _ := d.Enqueue("order-a", "A1", "corr-a")
+	jobB, _ := d.Enqueue("order-b", "B1", "corr-b")
+
+	waitForStatus(t, store, jobA.ID, StatusSucceeded)
+	waitForStatus(t, store, jobB.ID, StatusSucceeded)
+
+	if elapsed := time.Since(start); elapsed >= 2*slots.delay {
+		t.Fatalf("expected jobs for different slots to run in parallel, took %v", elapsed)
+	}
+}
+
+func TestDispenserRecoverInterruptedJobs(t *testing.T) {
+	store := newMemoryJobStore()
+	store.Save(Job{ID: "pending-job", Slot: "A1", Status: StatusPending, CreatedAt: time.Now()})
+	store.Save(Job{ID: "running-job", Slot: "A1", Status: StatusRunning, CreatedAt: time.Now(), StartedAt: time.Now()})
+
+	slots := &slowSlotDispenser{delay: time.Millisecond, running: map[string]bool{}}
+	d := NewDispenser(slots, store)
+
+	if err := d.RecoverInterruptedJobs(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	waitForStatus(t, store, "pending-job", StatusSucceeded)
+
+	runningJob := waitForStatus(t, store, "running-job", StatusFailed)
+	if runningJob.Error == "" {
+		t.Fatal("expected an interrupted running job to record an error")
+	}
+}
+
+func TestDispenserJobLookup(t *testing.T) {
+	store := newMemoryJobStore()
+	d := NewDispenser(&slowSlotDispenser{delay: time.Millisecond, running: map[string]bool{}}, store)
+
+	job, err := d.Enqueue("order-a", "A1", "corr-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok, err := d.Job(job.ID); err != nil || !ok {
+		t.Fatalf("expected to find job %s, ok=%v err=%v", job.ID, ok, err)
+	}
+
+	if _, ok, err := d.Job("does-not-exist"); err != nil || ok {
+		t.Fatalf("expected lookup of an unknown job to report not found, ok=%v err=%v", ok, err)
+	}
+}
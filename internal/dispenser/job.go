@@ -0,0 +1,28 @@
+package dispenser
+
+import "time"
+
+// Status is a Job's lifecycle state.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Job is one audited dispense, from the moment it's queued through its
+// outcome. Persisted by a JobStore so a restart doesn't lose track of an
+// in-flight relay pulse.
+type Job struct {
+	ID            string    `json:"id"`
+	OrderID       string    `json:"order_id"`
+	Slot          string    `json:"slot"`
+	Status        Status    `json:"status"`
+	Error         string    `json:"error,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	StartedAt     time.Time `json:"started_at,omitempty"`
+	EndedAt       time.Time `json:"ended_at,omitempty"`
+}
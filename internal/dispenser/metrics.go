@@ -0,0 +1,15 @@
+package dispenser
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var jobsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "dispenser_jobs_total",
+	Help: "Total number of dispense jobs completed, labeled by slot and outcome.",
+}, []string{"slot", "status"})
+
+func recordJobOutcome(slot string, status Status) {
+	jobsTotal.WithLabelValues(slot, string(status)).Inc()
+}
@@ -0,0 +1,92 @@
+package dispenser
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var jobsBucket = []byte("jobs")
+
+// JobStore is the persistent audit log behind a Dispenser: every job it
+// enqueues is saved here as it moves through its lifecycle, and reloaded
+// from here at boot to recover from a restart mid-dispense.
+type JobStore interface {
+	Save(job Job) error
+	Get(id string) (Job, bool, error)
+	List() ([]Job, error)
+}
+
+// BoltJobStore persists jobs to an on-disk BoltDB file, keyed by job ID.
+type BoltJobStore struct {
+	db *bolt.DB
+}
+
+// NewBoltJobStore opens (creating if necessary) a BoltDB-backed JobStore at
+// path.
+func NewBoltJobStore(path string) (*BoltJobStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(jobsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltJobStore{db: db}, nil
+}
+
+func (s *BoltJobStore) Save(job Job) error {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).Put([]byte(job.ID), raw)
+	})
+}
+
+func (s *BoltJobStore) Get(id string) (Job, bool, error) {
+	var job Job
+	var found bool
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(jobsBucket).Get([]byte(id))
+		if raw == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(raw, &job)
+	})
+
+	return job, found, err
+}
+
+func (s *BoltJobStore) List() ([]Job, error) {
+	var jobs []Job
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(jobsBucket).ForEach(func(_, raw []byte) error {
+			var job Job
+			if err := json.Unmarshal(raw, &job); err != nil {
+				return err
+			}
+			jobs = append(jobs, job)
+			return nil
+		})
+	})
+
+	return jobs, err
+}
+
+// Close releases the underlying BoltDB file.
+func (s *BoltJobStore) Close() error {
+	return s.db.Close()
+}
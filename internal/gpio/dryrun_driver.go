@@ -0,0 +1,30 @@
+package gpio
+
+import "log"
+
+// DryRunDriver logs every call instead of touching hardware, for
+// smoke-testing the webhook/dispense flow without any GPIO bus at all.
+type DryRunDriver struct{}
+
+// NewDryRunDriver returns a DryRunDriver.
+func NewDryRunDriver() *DryRunDriver {
+	return &DryRunDriver{}
+}
+
+func (d *DryRunDriver) Open() error {
+	log.Println("[gpio:dryrun] open")
+	return nil
+}
+
+func (d *DryRunDriver) Close() error {
+	log.Println("[gpio:dryrun] close")
+	return nil
+}
+
+func (d *DryRunDriver) SetMode(pin int, mode Mode) {
+	log.Printf("[gpio:dryrun] pin %d set mode %s", pin, mode)
+}
+
+func (d *DryRunDriver) Write(pin int, level Level) {
+	log.Printf("[gpio:dryrun] pin %d -> %s", pin, level)
+}
@@ -0,0 +1,44 @@
+// Package gpio abstracts the relay bus behind a small Driver interface, so
+// the vending logic can run against real Raspberry Pi hardware, a mock that
+// drives a browser-based virtual vending machine UI, or a dry-run driver
+// that only logs — selected by VENDING_GPIO_DRIVER.
+package gpio
+
+// Mode is a GPIO pin's direction.
+type Mode int
+
+const (
+	Input Mode = iota
+	Output
+)
+
+func (m Mode) String() string {
+	if m == Output {
+		return "output"
+	}
+	return "input"
+}
+
+// Level is a GPIO pin's logic level.
+type Level int
+
+const (
+	Low Level = iota
+	High
+)
+
+func (l Level) String() string {
+	if l == High {
+		return "high"
+	}
+	return "low"
+}
+
+// Driver is the bus-level abstraction every vending machine entrypoint
+// dispenses through.
+type Driver interface {
+	Open() error
+	Close() error
+	SetMode(pin int, mode Mode)
+	Write(pin int, level Level)
+}
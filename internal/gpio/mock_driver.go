@@ -0,0 +1,88 @@
+package gpio
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PinEvent is one recorded transition on a MockDriver, used both to answer
+// the test-only query endpoint and to push updates to a browser-based
+// virtual vending machine UI over WebSocket.
+type PinEvent struct {
+	Pin   int       `json:"pin"`
+	Level Level     `json:"level"`
+	At    time.Time `json:"at"`
+}
+
+// MockDriver records every pin transition in memory instead of touching
+// hardware, and fans new transitions out to subscribers.
+type MockDriver struct {
+	mu     sync.Mutex
+	events []PinEvent
+	subs   map[chan PinEvent]struct{}
+}
+
+// NewMockDriver returns a MockDriver with an empty event log.
+func NewMockDriver() *MockDriver {
+	return &MockDriver{subs: make(map[chan PinEvent]struct{})}
+}
+
+func (d *MockDriver) Open() error  { return nil }
+func (d *MockDriver) Close() error { return nil }
+
+// SetMode is a no-op: a browser UI only cares about level writes, not
+// direction changes.
+func (d *MockDriver) SetMode(pin int, mode Mode) {}
+
+func (d *MockDriver) Write(pin int, level Level) {
+	event := PinEvent{Pin: pin, Level: level, At: time.Now()}
+
+	d.mu.Lock()
+	d.events = append(d.events, event)
+	for ch := range d.subs {
+		select {
+		case ch <- event:
+		default:
+			// Slow subscriber: drop the event rather than block the dispense.
+		}
+	}
+	d.mu.Unlock()
+}
+
+// Events returns every recorded pin transition. Backs the test-only
+// /debug/gpio/events endpoint.
+func (d *MockDriver) Events() []PinEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]PinEvent(nil), d.events...)
+}
+
+// Subscribe registers a channel that receives future pin transitions. The
+// channel buffers 16 events; a transition written while it is full is dropped
+// for this subscriber. Call the returned unsubscribe func when done.
+func (d *MockDriver) Subscribe() (<-chan PinEvent, func()) {
+	ch := make(chan PinEvent, 16)
+
+	d.mu.Lock()
+	d.subs[ch] = struct{}{}
+	d.mu.Unlock()
+
+	unsubscribe := func() {
+		d.mu.Lock()
+		delete(d.subs, ch)
+		d.mu.Unlock()
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+// EventsHandler is a test-only HTTP endpoint exposing the recorded pin
+// transition log as JSON.
+func (d *MockDriver) EventsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(d.Events())
+	}
+}
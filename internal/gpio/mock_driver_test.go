@@ -0,0 +1,38 @@
+package gpio
+
+import "testing"
+
+func TestMockDriverRecordsEvents(t *testing.T) {
+	d := NewMockDriver()
+
+	d.Write(17, High)
+	d.Write(17, Low)
+
+	events := d.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 recorded events, got %d", len(events))
+	}
+	if events[0].Pin != 17 || events[0].Level != High {
+		t.Fatalf("unexpected first event: %+v", events[0])
+	}
+	if events[1].Level != Low {
+		t.Fatalf("unexpected second event: %+v", events[1])
+	}
+}
+
+func TestMockDriverSubscribeReceivesFutureEvents(t *testing.T) {
+	d := NewMockDriver()
+	ch, unsubscribe := d.Subscribe()
+	defer unsubscribe()
+
+	d.Write(3, High)
+
+	select {
+	case event := <-ch:
+		if event.Pin != 3 || event.Level != High {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+	default:
+		t.Fatal("expected a subscribed channel to receive the write immediately")
+	}
+}
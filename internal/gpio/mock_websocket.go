@@ -0,0 +1,36 @@
+package gpio
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{
+	// The virtual vending machine UI is typically served from a different
+	// origin in local dev (e.g. a Vite dev server), so allow cross-origin
+	// WebSocket upgrades.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// WebSocketHandler streams pin transitions recorded after connection to a
+// browser-based virtual vending machine UI. Transitions that arrive while the
+// connection's subscription buffer is full are dropped, not sent later.
+func (d *MockDriver) WebSocketHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		events, unsubscribe := d.Subscribe()
+		defer unsubscribe()
+
+		for event := range events {
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
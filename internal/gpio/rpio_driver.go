@@ -0,0 +1,45 @@
+package gpio
+
+import "github.com/stianeikeland/go-rpio"
+
+// RPIODriver drives real Raspberry Pi GPIO pins via go-rpio.
+type RPIODriver struct {
+	pins map[int]rpio.Pin
+}
+
+// NewRPIODriver returns an unopened RPIODriver. Call Open before SetMode or
+// Write.
+func NewRPIODriver() *RPIODriver {
+	return &RPIODriver{pins: make(map[int]rpio.Pin)}
+}
+
+func (d *RPIODriver) Open() error {
+	return rpio.Open()
+}
+
+func (d *RPIODriver) Close() error {
+	return rpio.Close()
+}
+
+func (d *RPIODriver) SetMode(pin int, mode Mode) {
+	p := rpio.Pin(pin)
+	if mode == Output {
+		p.Output()
+	} else {
+		p.Input()
+	}
+	d.pins[pin] = p
+}
+
+func (d *RPIODriver) Write(pin int, level Level) {
+	p, ok := d.pins[pin]
+	if !ok {
+		p = rpio.Pin(pin)
+		d.pins[pin] = p
+	}
+	if level == High {
+		p.High()
+	} else {
+		p.Low()
+	}
+}
@@ -0,0 +1,22 @@
+package gpio
+
+import (
+	"log"
+	"os"
+)
+
+// NewDriverFromEnv selects a Driver based on VENDING_GPIO_DRIVER ("rpio",
+// "mock", or "dryrun"). Defaults to "rpio" so existing deployments keep
+// driving real hardware unless they opt into a non-Pi driver.
+func NewDriverFromEnv() Driver {
+	switch os.Getenv("VENDING_GPIO_DRIVER") {
+	case "mock":
+		log.Println("Using mock GPIO driver")
+		return NewMockDriver()
+	case "dryrun":
+		log.Println("Using dry-run GPIO driver")
+		return NewDryRunDriver()
+	default:
+		return NewRPIODriver()
+	}
+}
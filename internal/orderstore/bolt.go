@@ -0,0 +1,136 @@
+package orderstore
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var ordersBucket = []byte("orders")
+
+// janitorInterval is how often BoltStore sweeps its bucket for expired
+// entries. Expiry is also checked inline on every MarkProcessed call, so
+// this only bounds how long a never-revisited order lingers on disk.
+const janitorInterval = time.Hour
+
+// BoltStore is an OrderDedupeStore backed by a local BoltDB file, giving
+// process-restart durability without standing up a Redis instance. Unlike
+// RedisStore it isn't shared across replicas, so it's best suited to a
+// single-instance deployment.
+type BoltStore struct {
+	db          *bolt.DB
+	ttl         time.Duration
+	stopJanitor chan struct{}
+}
+
+// NewBoltStore opens (creating if necessary) a BoltDB file at path. Pass
+// ttl <= 0 to use ProcessedOrderTTL. Call Close when done to release the
+// file handle and stop the background janitor.
+func NewBoltStore(path string, ttl time.Duration) (*BoltStore, error) {
+	if ttl <= 0 {
+		ttl = ProcessedOrderTTL
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(ordersBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &BoltStore{db: db, ttl: ttl, stopJanitor: make(chan struct{})}
+	go s.runJanitor()
+	return s, nil
+}
+
+func (s *BoltStore) MarkProcessed(_ context.Context, orderID string) (bool, error) {
+	now := time.Now()
+	var alreadyProcessed bool
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(ordersBucket)
+		if raw := b.Get([]byte(orderID)); raw != nil && now.Before(decodeExpiry(raw)) {
+			alreadyProcessed = true
+			return nil
+		}
+		return b.Put([]byte(orderID), encodeExpiry(now.Add(s.ttl)))
+	})
+	if err != nil {
+		return false, err
+	}
+
+	recordLookup("bolt", alreadyProcessed)
+	return alreadyProcessed, nil
+}
+
+// Size reports the number of (possibly expired but not yet swept) entries
+// currently stored on disk.
+func (s *BoltStore) Size(_ context.Context) (int64, error) {
+	var n int64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		n = int64(tx.Bucket(ordersBucket).Stats().KeyN)
+		return nil
+	})
+	return n, err
+}
+
+// Close stops the background janitor and releases the BoltDB file handle.
+func (s *BoltStore) Close() error {
+	close(s.stopJanitor)
+	return s.db.Close()
+}
+
+func (s *BoltStore) runJanitor() {
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.evictExpired()
+		case <-s.stopJanitor:
+			return
+		}
+	}
+}
+
+// evictExpired removes every entry whose TTL has passed. Errors are
+// swallowed: a failed sweep just means expired entries linger until the
+// next tick, which is harmless since MarkProcessed re-checks expiry inline.
+func (s *BoltStore) evictExpired() {
+	now := time.Now()
+	_ = s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(ordersBucket)
+		c := b.Cursor()
+
+		var expired [][]byte
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if now.After(decodeExpiry(v)) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+		}
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func encodeExpiry(t time.Time) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(t.UnixNano()))
+	return buf
+}
+
+func decodeExpiry(raw []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(raw)))
+}
@@ -0,0 +1,25 @@
+package orderstore
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// dedupeLookups counts every successful MarkProcessed call (a call that
+// returns an error is not counted), labeled by which backend
+// served it and whether the order/event had already been seen. This is
+// registered here (rather than by each binary) so every OrderDedupeStore
+// implementation is instrumented the same way regardless of which
+// entrypoint embeds it.
+var dedupeLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "orderstore_dedupe_lookups_total",
+	Help: "Total MarkProcessed calls against an order dedupe store, labeled by backend and hit/miss.",
+}, []string{"store", "result"})
+
+func recordLookup(store string, alreadyProcessed bool) {
+	result := "miss"
+	if alreadyProcessed {
+		result = "hit"
+	}
+	dedupeLookups.WithLabelValues(store, result).Inc()
+}
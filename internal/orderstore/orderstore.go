@@ -0,0 +1,163 @@
+// Package orderstore provides the idempotency store shared by every vending
+// machine entrypoint: a webhook retried by Square must not dispense twice.
+package orderstore
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ProcessedOrderTTL is how long a processed order ID is remembered. Square
+// retries failed webhook deliveries for up to 72 hours, so a week gives a
+// comfortable margin.
+const ProcessedOrderTTL = 7 * 24 * time.Hour
+
+// OrderDedupeStore records which Square order IDs have already been
+// dispensed so a retried webhook delivery is a no-op.
+type OrderDedupeStore interface {
+	// MarkProcessed atomically records orderID as processed and reports
+	// whether it had already been marked before this call.
+	MarkProcessed(ctx context.Context, orderID string) (alreadyProcessed bool, err error)
+}
+
+// Sizer is implemented by stores that can report how many entries they
+// currently hold, for metrics sampling.
+type Sizer interface {
+	Size(ctx context.Context) (int64, error)
+}
+
+// setNXer is the subset of go-redis calls MarkProcessed and Size need;
+// narrowing the dependency to this makes RedisStore testable without a
+// real server.
+type setNXer interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	DBSize(ctx context.Context) *redis.IntCmd
+}
+
+// RedisStore is an OrderDedupeStore backed by Redis, shared across replicas
+// and surviving process restarts.
+type RedisStore struct {
+	client setNXer
+	ttl    time.Duration
+}
+
+// NewRedisStore wraps an existing Redis client. Pass ttl <= 0 to use
+// ProcessedOrderTTL.
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	if ttl <= 0 {
+		ttl = ProcessedOrderTTL
+	}
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+func (s *RedisStore) MarkProcessed(ctx context.Context, orderID string) (bool, error) {
+	set, err := s.client.SetNX(ctx, "order:"+orderID, 1, s.ttl).Result()
+	if err != nil {
+		return false, err
+	}
+	// SetNX reports true when the key was newly set, i.e. the order was not
+	// processed before.
+	alreadyProcessed := !set
+	recordLookup("redis", alreadyProcessed)
+	return alreadyProcessed, nil
+}
+
+// Size reports the number of keys in the Redis database used for dedupe.
+// This is a database-wide count (Redis has no cheap way to count only keys
+// matching a prefix), so dedicate a DB index to order dedupe if you need an
+// exact figure.
+func (s *RedisStore) Size(ctx context.Context) (int64, error) {
+	return s.client.DBSize(ctx).Result()
+}
+
+// defaultMaxEntries bounds a MemoryStore when no explicit capacity is given
+// (maxEntries <= 0), so a store built without one still can't grow without
+// bound.
+const defaultMaxEntries = 100_000
+
+// memoryEntry is the value held at each list.Element in MemoryStore.order.
+type memoryEntry struct {
+	orderID string
+	expiry  time.Time
+}
+
+// MemoryStore is a bounded, in-process OrderDedupeStore for local
+// development and tests that don't have a Redis instance handy. Entries
+// expire after ttl, and the least-recently-marked entry is evicted once
+// maxEntries is reached, so the map can't grow without bound even if some
+// orders are never revisited. State is lost on restart.
+type MemoryStore struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List               // front = most recently marked
+	elements   map[string]*list.Element // orderID -> its node in order
+}
+
+// NewMemoryStore returns a MemoryStore. Pass ttl <= 0 to use
+// ProcessedOrderTTL, and maxEntries <= 0 to use defaultMaxEntries.
+func NewMemoryStore(ttl time.Duration, maxEntries int) *MemoryStore {
+	if ttl <= 0 {
+		ttl = ProcessedOrderTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	return &MemoryStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		elements:   make(map[string]*list.Element),
+	}
+}
+
+func (s *MemoryStore) MarkProcessed(_ context.Context, orderID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if el, ok := s.elements[orderID]; ok {
+		entry := el.Value.(*memoryEntry)
+		if now.Before(entry.expiry) {
+			s.order.MoveToFront(el)
+			recordLookup("memory", true)
+			return true, nil
+		}
+		// Expired: treat it as unseen and fall through to re-mark it.
+		s.order.Remove(el)
+		delete(s.elements, orderID)
+	}
+
+	el := s.order.PushFront(&memoryEntry{orderID: orderID, expiry: now.Add(s.ttl)})
+	s.elements[orderID] = el
+	s.evictOldestLocked()
+
+	recordLookup("memory", false)
+	return false, nil
+}
+
+// evictOldestLocked removes least-recently-marked entries until the store is
+// back within maxEntries. Callers must hold s.mu.
+func (s *MemoryStore) evictOldestLocked() {
+	for s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		if oldest == nil {
+			return
+		}
+		entry := oldest.Value.(*memoryEntry)
+		s.order.Remove(oldest)
+		delete(s.elements, entry.orderID)
+	}
+}
+
+// Size reports the number of (possibly expired but not yet evicted) entries
+// currently held in memory.
+func (s *MemoryStore) Size(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(s.order.Len()), nil
+}
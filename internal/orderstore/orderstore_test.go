@@ -0,0 +1,151 @@
+package orderstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMemoryStoreMarksDuplicates(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 0)
+	ctx := context.Background()
+
+	dup, err := store.MarkProcessed(ctx, "order_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dup {
+		t.Fatal("expected first call to report not-a-duplicate")
+	}
+
+	dup, err = store.MarkProcessed(ctx, "order_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !dup {
+		t.Fatal("expected second call for the same order to report a duplicate")
+	}
+}
+
+func TestMemoryStoreExpiresEntries(t *testing.T) {
+	store := NewMemoryStore(time.Millisecond, 0)
+	ctx := context.Background()
+
+	if _, err := store.MarkProcessed(ctx, "order_1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	dup, err := store.MarkProcessed(ctx, "order_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dup {
+		t.Fatal("expected expired entry to no longer count as a duplicate")
+	}
+}
+
+func TestMemoryStoreEvictsOldestBeyondCapacity(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 2)
+	ctx := context.Background()
+
+	for _, id := range []string{"order_1", "order_2", "order_3"} {
+		if _, err := store.MarkProcessed(ctx, id); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	size, err := store.Size(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size != 2 {
+		t.Fatalf("expected store to be capped at 2 entries, got %d", size)
+	}
+
+	dup, err := store.MarkProcessed(ctx, "order_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dup {
+		t.Fatal("expected order_1 to have been evicted to make room for order_3")
+	}
+}
+
+// fakeSetNX is a minimal setNXer used to exercise RedisStore without a real
+// Redis server.
+type fakeSetNX struct {
+	seen map[string]bool
+}
+
+func (f *fakeSetNX) SetNX(_ context.Context, key string, _ interface{}, _ time.Duration) *redis.BoolCmd {
+	cmd := redis.NewBoolCmd(context.Background())
+	cmd.SetVal(!f.seen[key])
+	f.seen[key] = true
+	return cmd
+}
+
+func (f *fakeSetNX) DBSize(_ context.Context) *redis.IntCmd {
+	cmd := redis.NewIntCmd(context.Background())
+	cmd.SetVal(int64(len(f.seen)))
+	return cmd
+}
+
+func TestBoltStoreMarksDuplicatesAndSurvivesReopen(t *testing.T) {
+	path := t.TempDir() + "/orders.db"
+	ctx := context.Background()
+
+	store, err := NewBoltStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error opening store: %v", err)
+	}
+
+	dup, err := store.MarkProcessed(ctx, "order_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dup {
+		t.Fatal("expected first call to report not-a-duplicate")
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("unexpected error closing store: %v", err)
+	}
+
+	reopened, err := NewBoltStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error reopening store: %v", err)
+	}
+	defer reopened.Close()
+
+	dup, err = reopened.MarkProcessed(ctx, "order_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !dup {
+		t.Fatal("expected order_1 to still be marked processed after reopening the store")
+	}
+}
+
+func TestRedisStoreMarksDuplicates(t *testing.T) {
+	store := &RedisStore{client: &fakeSetNX{seen: map[string]bool{}}, ttl: time.Hour}
+	ctx := context.Background()
+
+	dup, err := store.MarkProcessed(ctx, "order_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dup {
+		t.Fatal("expected first call to report not-a-duplicate")
+	}
+
+	dup, err = store.MarkProcessed(ctx, "order_1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !dup {
+		t.Fatal("expected second call for the same order to report a duplicate")
+	}
+}
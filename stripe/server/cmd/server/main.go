@@ -2,37 +2,214 @@ package main
 
 import (
 	"context"
-	"ieeeuottawa/vend-server/internal/router"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/api/handler/vending"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/logging"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/repository"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/router"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/service"
+
+	"github.com/ieee-uottawa/vending-machine/internal/dispenser"
+	"github.com/ieee-uottawa/vending-machine/internal/gpio"
+	"github.com/ieee-uottawa/vending-machine/internal/orderstore"
+	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
 )
 
-var ctx = context.Background()
+// physicalPins maps logical relay pin numbers to the Raspberry Pi's
+// physical GPIO pin numbers.
+var physicalPins = map[int]int{
+	1: 2, 2: 3, 3: 4, 4: 17, 5: 27, 6: 22, 7: 10, 8: 9,
+	9: 11, 10: 5, 11: 6, 12: 13, 13: 19, 14: 26, 15: 14, 16: 15,
+}
 
-func main() {
-	r := router.NewRouter()
+// slotRelays maps slot labels to the logical relay pins that must fire
+// together to dispense from that slot.
+var slotRelays = map[string][]int{
+	"A1": {3, 12, 13, 14}, "A2": {3, 7, 13, 14}, "A3": {3, 7, 12, 14}, "A4": {3, 7, 12, 13},
+	"B1": {2, 12, 13, 14}, "B2": {2, 7, 13, 14}, "B3": {2, 7, 12, 14}, "B4": {2, 7, 12, 13},
+	"C1": {5, 12, 13, 14}, "C2": {5, 7, 13, 14}, "C3": {5, 7, 12, 14}, "C4": {5, 7, 12, 13},
+	"D1": {4, 16, 15, 14, 13, 12, 10, 8}, "D2": {4, 16, 15, 14, 13, 10, 8, 7},
+	"D3": {4, 16, 15, 14, 12, 10, 8, 7}, "D4": {4, 16, 15, 13, 12, 10, 8, 7},
+	"D5": {4, 16, 14, 13, 12, 7, 8, 10}, "D6": {4, 16, 14, 13, 12, 7, 8, 15},
+	"D7": {4, 15, 14, 13, 12, 10, 8, 7}, "D8": {4, 16, 15, 14, 13, 12, 10, 7},
+	"E1": {1, 16, 15, 14, 13, 12, 10, 8}, "E2": {1, 16, 15, 14, 13, 10, 8, 7},
+	"E3": {1, 16, 15, 14, 12, 10, 8, 7}, "E4": {1, 16, 15, 13, 12, 10, 8, 7},
+	"E5": {1, 16, 14, 13, 12, 7, 8, 10}, "E6": {1, 16, 14, 13, 12, 7, 8, 15},
+	"E7": {1, 15, 14, 13, 12, 10, 8, 7}, "E8": {1, 16, 15, 14, 13, 12, 10, 7},
+	"F1": {6, 12, 13, 14}, "F2": {6, 7, 13, 14}, "F3": {6, 7, 12, 14}, "F4": {6, 7, 12, 13},
+}
+
+// openRelayPins opens the GPIO driver and sets every physical pin to output
+// HIGH (relays are active LOW, so HIGH = off).
+func openRelayPins(driver gpio.Driver) error {
+	if err := driver.Open(); err != nil {
+		return err
+	}
+
+	for _, physicalPin := range physicalPins {
+		driver.SetMode(physicalPin, gpio.Output)
+		driver.Write(physicalPin, gpio.High)
+	}
+	return nil
+}
+
+// newOrderStore builds an OrderDedupeStore from VENDING_ORDER_STORE
+// ("redis", "bolt", or "memory", default "redis" when VENDING_REDIS_ADDR is
+// set and "memory" otherwise).
+func newOrderStore() orderstore.OrderDedupeStore {
+	mode := os.Getenv("VENDING_ORDER_STORE")
+	if mode == "" {
+		mode = "redis"
+	}
 
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     "db-redis:6379",
-		Password: "",
-		DB:       0,
-		Protocol: 2,
+	switch mode {
+	case "bolt":
+		path := os.Getenv("VENDING_ORDERSTORE_PATH")
+		if path == "" {
+			path = "./data/orders.db"
+		}
+		store, err := orderstore.NewBoltStore(path, orderstore.ProcessedOrderTTL)
+		if err != nil {
+			log.Fatalf("Failed to open bolt order dedupe store at %s: %v", path, err)
+		}
+		return store
+	case "memory":
+		return orderstore.NewMemoryStore(orderstore.ProcessedOrderTTL, 0)
+	}
+
+	addr := os.Getenv("VENDING_REDIS_ADDR")
+	if addr == "" {
+		log.Println("VENDING_REDIS_ADDR not set, using in-memory order dedupe store")
+		return orderstore.NewMemoryStore(orderstore.ProcessedOrderTTL, 0)
+	}
+
+	db := 0
+	if dbStr := os.Getenv("VENDING_REDIS_DB"); dbStr != "" {
+		parsed, err := strconv.Atoi(dbStr)
+		if err != nil {
+			log.Printf("Invalid VENDING_REDIS_DB %q, defaulting to 0", dbStr)
+		} else {
+			db = parsed
+		}
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: os.Getenv("VENDING_REDIS_PASSWORD"),
+		DB:       db,
 	})
 
-	err := rdb.Set(ctx, "foo", "bar", 0).Err()
+	return orderstore.NewRedisStore(client, orderstore.ProcessedOrderTTL)
+}
+
+// webhookMaxAge returns the freshness window for incoming Square webhooks
+// from VENDING_WEBHOOK_MAX_AGE (a duration string like "72h"), falling back
+// to service.DefaultWebhookMaxAge when unset or unparseable.
+func webhookMaxAge() time.Duration {
+	raw := os.Getenv("VENDING_WEBHOOK_MAX_AGE")
+	if raw == "" {
+		return service.DefaultWebhookMaxAge
+	}
+
+	age, err := time.ParseDuration(raw)
 	if err != nil {
-		panic(err)
+		log.Printf("Invalid VENDING_WEBHOOK_MAX_AGE %q, defaulting to %s", raw, service.DefaultWebhookMaxAge)
+		return service.DefaultWebhookMaxAge
+	}
+	return age
+}
+
+// newJobStore opens the BoltDB-backed audit log behind the dispenser, at
+// VENDING_JOBSTORE_PATH (default "./data/jobs.db").
+func newJobStore() *dispenser.BoltJobStore {
+	path := os.Getenv("VENDING_JOBSTORE_PATH")
+	if path == "" {
+		path = "./data/jobs.db"
 	}
 
-	val, err := rdb.Get(ctx, "foo").Result()
+	store, err := dispenser.NewBoltJobStore(path)
 	if err != nil {
-		panic(err)
+		log.Fatalf("Failed to open dispense job store at %s: %v", path, err)
 	}
+	return store
+}
+
+func main() {
+	// Every package that logs via the slog package-level helpers (e.g.
+	// dispenser's jobLogger) goes through slog.Default(), so it has to be
+	// installed here - otherwise those lines fall back to slog's default
+	// text handler on stderr instead of logging.Base's JSON handler, and
+	// order/slot/duration fields stop being machine-grep-able.
+	slog.SetDefault(logging.Base)
 
-	log.Println("foo: ", val)
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Could not load .env file: %v", err)
+	}
+
+	log.Println("Initializing GPIO...")
+	driver := gpio.NewDriverFromEnv()
+	if err := openRelayPins(driver); err != nil {
+		log.Fatalf("Failed to initialize GPIO: %v", err)
+	}
+	defer driver.Close()
+	log.Println("GPIO initialization complete")
+
+	notificationURL := os.Getenv("SQUARE_WEBHOOK_NOTIFICATION_URL")
+	if notificationURL == "" {
+		log.Println("Warning: SQUARE_WEBHOOK_NOTIFICATION_URL not set, webhook signature checks will fail")
+	}
+
+	slots := repository.NewSlotRepository(driver, physicalPins, slotRelays)
+
+	jobDispenser := dispenser.NewDispenser(slots, newJobStore())
+	if err := jobDispenser.RecoverInterruptedJobs(); err != nil {
+		log.Printf("Warning: failed to recover dispense jobs from a previous run: %v", err)
+	}
+
+	vendingSvc := service.NewVendingService(
+		slots,
+		jobDispenser,
+		newOrderStore(),
+		&http.Client{Timeout: 30 * time.Second},
+		os.Getenv("SQUARE_ACCESS_TOKEN_PROD"),
+		"https://connect.squareup.com/v2",
+		os.Getenv("SQUARE_WEBHOOK_SIGNATURE_KEY"),
+		notificationURL,
+		webhookMaxAge(),
+	)
+	if os.Getenv("VENDING_CATALOG_WARMUP") == "true" {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			log.Println("Warming catalog cache...")
+			if err := vendingSvc.WarmCatalogCache(ctx); err != nil {
+				log.Printf("Warning: catalog cache warmup failed: %v", err)
+				return
+			}
+			log.Println("Catalog cache warmup complete")
+		}()
+	}
+
+	vendingHandler := vending.NewVendingHandler(vendingSvc, os.Getenv("VENDING_ADMIN_TOKEN"))
+
+	r := router.NewRouter(vendingHandler)
+
+	// The mock GPIO driver records every pin transition so a browser-based
+	// virtual vending machine UI can watch dispenses happen in real time.
+	if mockDriver, ok := driver.(*gpio.MockDriver); ok {
+		r.Get("/debug/gpio/events", mockDriver.EventsHandler())
+		r.Get("/debug/gpio/stream", mockDriver.WebSocketHandler())
+	}
 
 	log.Println("Server starting on port :3000")
-	http.ListenAndServe(":3000", r)
+	if err := http.ListenAndServe(":3000", r); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
 }
@@ -2,7 +2,7 @@ package handler
 
 import (
 	"encoding/json"
-	"ieeeuottawa/vend-server/internal/service"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/service"
 	"net/http"
 	"strconv"
 
@@ -0,0 +1,16 @@
+package vending
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var webhooksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "vending_webhooks_total",
+	Help: "Total number of Square webhook deliveries received, labeled by result.",
+}, []string{"result"})
+
+const (
+	resultAccepted = "accepted"
+	resultRejected = "rejected"
+)
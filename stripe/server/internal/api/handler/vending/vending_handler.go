@@ -0,0 +1,170 @@
+package vending
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/logging"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/model"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// maxWebhookBodyBytes bounds how large a Square webhook delivery we'll read,
+// to stop a malicious or misbehaving sender from exhausting memory.
+const maxWebhookBodyBytes = 1 << 20 // 1 MiB
+
+// VendingHandler exposes the Square webhook, admin dispense, and dispense
+// job status endpoints.
+type VendingHandler struct {
+	service    service.VendingService
+	adminToken string
+}
+
+// NewVendingHandler wires a VendingHandler. adminToken gates the dispense
+// endpoint; requests must present "Authorization: Bearer <adminToken>".
+func NewVendingHandler(service service.VendingService, adminToken string) *VendingHandler {
+	return &VendingHandler{service: service, adminToken: adminToken}
+}
+
+func (h *VendingHandler) Routes() http.Handler {
+	r := chi.NewRouter()
+	r.Post("/webhook/square", h.HandleSquareWebhook)
+	r.Post("/dispense/{slot}", h.HandleDispense)
+	r.Get("/jobs", h.HandleListJobs)
+	r.Get("/jobs/{id}", h.HandleGetJob)
+	r.Post("/cache/invalidate", h.HandleInvalidateCache)
+	return r
+}
+
+// authorizedAdmin reports whether the request carries the configured admin
+// bearer token, compared in constant time.
+func (h *VendingHandler) authorizedAdmin(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return h.adminToken != "" && hmac.Equal([]byte(token), []byte(h.adminToken))
+}
+
+func (h *VendingHandler) HandleSquareWebhook(w http.ResponseWriter, r *http.Request) {
+	// chi's RequestID middleware already stamped this request; reusing it as
+	// the correlation ID means every log line from here through the eventual
+	// dispense can be grepped back together without minting a second ID.
+	ctx := logging.WithCorrelationID(r.Context(), middleware.GetReqID(r.Context()))
+	logger := logging.FromContext(ctx, "vending_handler")
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Warn("failed to read webhook body", "error", err)
+		webhooksTotal.WithLabelValues(resultRejected).Inc()
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	signature := r.Header.Get("x-square-hmacsha256-signature")
+	if !h.service.VerifyWebhookSignature(body, signature) {
+		logger.Warn("rejecting webhook: signature verification failed")
+		webhooksTotal.WithLabelValues(resultRejected).Inc()
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload model.SquareWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		logger.Warn("invalid webhook payload", "error", err)
+		webhooksTotal.WithLabelValues(resultRejected).Inc()
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	webhooksTotal.WithLabelValues(resultAccepted).Inc()
+
+	// Process the webhook in the background so a slow Square API or a
+	// multi-second dispense pulse doesn't hold the connection open. net/http
+	// cancels r.Context() the moment this handler returns, so the background
+	// context must start from context.Background() rather than ctx -
+	// otherwise ProcessSquareEvent's first Square API call fails with
+	// "context canceled" before it even starts. The correlation ID is carried
+	// forward as a plain value by re-attaching it to the detached context.
+	correlationID := logging.CorrelationID(ctx)
+	go func() {
+		dispatchCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		dispatchCtx = logging.WithCorrelationID(dispatchCtx, correlationID)
+		if err := h.service.ProcessSquareEvent(dispatchCtx, payload); err != nil {
+			logger.Warn("error processing webhook", "error", err)
+		}
+	}()
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"message":"Webhook received and processing started"}`))
+}
+
+func (h *VendingHandler) HandleDispense(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizedAdmin(r) {
+		http.Error(w, "Invalid admin token", http.StatusUnauthorized)
+		return
+	}
+
+	slot := chi.URLParam(r, "slot")
+	job, err := h.service.Dispense(r.Context(), slot)
+	if err != nil {
+		logging.FromContext(r.Context(), "vending_handler").Warn("dispense failed", "slot", slot, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+// HandleListJobs returns every dispense job the service has recorded.
+func (h *VendingHandler) HandleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.service.Jobs()
+	if err != nil {
+		logging.FromContext(r.Context(), "vending_handler").Error("failed to list dispense jobs", "error", err)
+		http.Error(w, "Failed to list jobs", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(jobs)
+}
+
+// HandleGetJob returns a single dispense job by ID.
+func (h *VendingHandler) HandleGetJob(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	job, ok, err := h.service.Job(id)
+	if err != nil {
+		logging.FromContext(r.Context(), "vending_handler").Error("failed to look up dispense job", "job_id", id, "error", err)
+		http.Error(w, "Failed to look up job", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+// HandleInvalidateCache drops every cached catalog object -> slot label
+// mapping, forcing the next webhook to re-resolve each item against Square.
+func (h *VendingHandler) HandleInvalidateCache(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizedAdmin(r) {
+		http.Error(w, "Invalid admin token", http.StatusUnauthorized)
+		return
+	}
+
+	h.service.InvalidateCatalogCache()
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"message":"catalog cache invalidated"}`))
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// Base is the process-wide structured logger: JSON to stdout so the fields
+// threaded through a webhook's lifecycle (order_id, slot, catalog_object_id,
+// duration_ms, ...) stay machine-grep-able instead of buried in a free-text
+// message.
+var Base = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+type correlationIDKey struct{}
+
+// WithCorrelationID attaches a correlation ID to ctx. Handlers seed this
+// from chi's request ID at webhook receipt, so every log line emitted while
+// a delivery is processed - across the order fetch, catalog lookups, and the
+// eventual dispense - can be grepped back together by that one ID.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID attached to ctx, or "" if none
+// was set.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// FromContext returns Base tagged with ctx's correlation ID and component,
+// ready to have call-specific fields (order_id, slot, ...) layered on with
+// .With(...) at each log site.
+func FromContext(ctx context.Context, component string) *slog.Logger {
+	return Base.With("component", component, "correlation_id", CorrelationID(ctx))
+}
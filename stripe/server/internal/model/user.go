@@ -0,0 +1,9 @@
+package model
+
+// User is a vending machine account holder.
+type User struct {
+	Id        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
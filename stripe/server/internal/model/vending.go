@@ -0,0 +1,63 @@
+package model
+
+// SquareWebhookPayload is the envelope Square sends for every webhook
+// delivery. We only care about completed payments.
+type SquareWebhookPayload struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	CreatedAt string `json:"created_at"`
+	Data      struct {
+		Object struct {
+			Payment struct {
+				Status  string `json:"status"`
+				OrderID string `json:"order_id"`
+			} `json:"payment"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// SquareOrderResponse is the subset of Square's Orders API response this
+// service reads: which catalog items the order paid for.
+type SquareOrderResponse struct {
+	Order struct {
+		LineItems []struct {
+			CatalogObjectID string `json:"catalog_object_id"`
+			UID             string `json:"uid"`
+		} `json:"line_items"`
+	} `json:"order"`
+}
+
+// SquareCatalogResponse is the subset of Square's Catalog API response
+// holding the custom attribute that maps a catalog item to a vending slot.
+type SquareCatalogResponse struct {
+	Object struct {
+		CustomAttributeValues map[string]struct {
+			CustomAttributeDefinitionID string   `json:"custom_attribute_definition_id"`
+			SelectionUIDValues          []string `json:"selection_uid_values"`
+		} `json:"custom_attribute_values"`
+	} `json:"object"`
+}
+
+// SquareDefinitionResponse is the subset of Square's Catalog API response
+// holding the selection UID -> slot label mapping for a custom attribute.
+type SquareDefinitionResponse struct {
+	Object struct {
+		CustomAttributeDefinitionData struct {
+			SelectionConfig struct {
+				AllowedSelections []struct {
+					UID  string `json:"uid"`
+					Name string `json:"name"`
+				} `json:"allowed_selections"`
+			} `json:"selection_config"`
+		} `json:"custom_attribute_definition_data"`
+	} `json:"object"`
+}
+
+// SquareCatalogListResponse is the subset of Square's List Catalog API
+// response used to page through every catalog item at cache warmup.
+type SquareCatalogListResponse struct {
+	Objects []struct {
+		ID string `json:"id"`
+	} `json:"objects"`
+	Cursor string `json:"cursor"`
+}
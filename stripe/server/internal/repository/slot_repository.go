@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ieee-uottawa/vending-machine/internal/gpio"
+)
+
+// dispenseDuration is how long a slot's relays stay energized for one
+// dispense. A var, not a const, so tests can shrink it instead of sleeping
+// for real.
+var dispenseDuration = 3300 * time.Millisecond
+
+// SlotRepository owns the logical-to-physical relay pin mapping for each
+// vending slot and knows how to energize them for a dispense through a
+// gpio.Driver.
+type SlotRepository interface {
+	// HasSlot reports whether slotLabel is a known vending slot.
+	HasSlot(slotLabel string) bool
+
+	// Dispense energizes every relay for slotLabel (active LOW) for
+	// dispenseDuration, then deactivates them (HIGH). Blocks for the
+	// duration of the dispense; callers that want non-blocking behavior
+	// should run it in a goroutine.
+	Dispense(slotLabel string) error
+}
+
+type slotRepository struct {
+	driver       gpio.Driver
+	physicalPins map[int]int // logical pin -> physical pin
+	slotRelays   map[string][]int
+}
+
+// NewSlotRepository wraps an already-opened gpio.Driver, the logical-to-
+// physical pin mapping, and the slot-to-pin mapping loaded at startup.
+func NewSlotRepository(driver gpio.Driver, physicalPins map[int]int, slotRelays map[string][]int) SlotRepository {
+	return &slotRepository{driver: driver, physicalPins: physicalPins, slotRelays: slotRelays}
+}
+
+func (r *slotRepository) HasSlot(slotLabel string) bool {
+	_, ok := r.slotRelays[slotLabel]
+	return ok
+}
+
+func (r *slotRepository) Dispense(slotLabel string) error {
+	relays, ok := r.slotRelays[slotLabel]
+	if !ok {
+		return fmt.Errorf("unknown slot label: %s", slotLabel)
+	}
+
+	for _, logicalPin := range relays {
+		if physicalPin, ok := r.physicalPins[logicalPin]; ok {
+			r.driver.Write(physicalPin, gpio.Low)
+		}
+	}
+
+	time.Sleep(dispenseDuration)
+
+	for _, logicalPin := range relays {
+		if physicalPin, ok := r.physicalPins[logicalPin]; ok {
+			r.driver.Write(physicalPin, gpio.High)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ieee-uottawa/vending-machine/internal/gpio"
+)
+
+func TestSlotRepositoryDispenseEnergizesAndReleasesRelays(t *testing.T) {
+	orig := dispenseDuration
+	dispenseDuration = time.Millisecond
+	defer func() { dispenseDuration = orig }()
+
+	driver := gpio.NewMockDriver()
+	physicalPins := map[int]int{1: 101, 2: 102}
+	repo := NewSlotRepository(driver, physicalPins, map[string][]int{"A1": {1, 2}})
+
+	if err := repo.Dispense("A1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lastLevel := map[int]gpio.Level{}
+	for _, event := range driver.Events() {
+		lastLevel[event.Pin] = event.Level
+	}
+	for _, physicalPin := range physicalPins {
+		if lastLevel[physicalPin] != gpio.High {
+			t.Fatalf("expected physical pin %d to end HIGH, got %v", physicalPin, lastLevel[physicalPin])
+		}
+	}
+}
+
+func TestSlotRepositoryDispenseUnknownSlot(t *testing.T) {
+	repo := NewSlotRepository(gpio.NewMockDriver(), map[int]int{}, map[string][]int{})
+	if err := repo.Dispense("Z9"); err == nil {
+		t.Fatal("expected dispensing an unknown slot to return an error")
+	}
+}
+
+func TestSlotRepositoryHasSlot(t *testing.T) {
+	repo := NewSlotRepository(gpio.NewMockDriver(), map[int]int{}, map[string][]int{"A1": {1}})
+	if !repo.HasSlot("A1") {
+		t.Fatal("expected HasSlot to report true for a known slot")
+	}
+	if repo.HasSlot("Z9") {
+		t.Fatal("expected HasSlot to report false for an unknown slot")
+	}
+}
@@ -1,7 +1,7 @@
 package repository
 
 import (
-	"ieeeuottawa/vend-server/internal/model"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/model"
 )
 
 type UserRepository interface {
@@ -1,16 +1,22 @@
 package router
 
 import (
-	"ieeeuottawa/vend-server/internal/api/handler"
-	"ieeeuottawa/vend-server/internal/repository"
-	"ieeeuottawa/vend-server/internal/service"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/api/handler"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/api/handler/vending"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/repository"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/service"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func NewRouter() *chi.Mux {
+// NewRouter builds the unified chi router, mounting every feature's routes
+// under /api. vendingHandler is nil-safe: a nil handler simply leaves
+// /api/vending unmounted, which keeps this router usable in tests that don't
+// care about GPIO.
+func NewRouter(vendingHandler *vending.VendingHandler) *chi.Mux {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
@@ -23,12 +29,17 @@ func NewRouter() *chi.Mux {
 	// processing should be stopped.
 	r.Use(middleware.Timeout(60 * time.Second))
 
+	r.Get("/metrics", promhttp.Handler().ServeHTTP)
+
 	userRepo := repository.NewUserRepository()
 	userService := service.NewUserService(userRepo)
 	userHandler := handler.NewUserHandler(userService)
 
 	r.Route("/api", func(r chi.Router) {
 		r.Mount("/users", userHandler.Routes())
+		if vendingHandler != nil {
+			r.Mount("/vending", vendingHandler.Routes())
+		}
 	})
 	return r
 }
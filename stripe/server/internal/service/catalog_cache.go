@@ -0,0 +1,89 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/model"
+)
+
+// catalogCacheTTL bounds how long a resolved slot label or attribute
+// definition is trusted before it's re-fetched from Square. Catalog objects
+// and their definitions change rarely, so a modest TTL keeps a 4-item order
+// from costing 8 Square round-trips without risking a stale mapping for
+// long after a real catalog edit.
+const catalogCacheTTL = 10 * time.Minute
+
+type slotLabelCacheEntry struct {
+	slotLabel string
+	expires   time.Time
+}
+
+type definitionCacheEntry struct {
+	definition model.SquareDefinitionResponse
+	expires    time.Time
+}
+
+// catalogCache caches getSlotLabelFromCatalogObject's two Square lookups
+// (catalog object -> slot label, and the custom attribute definition it
+// depends on) and coalesces concurrent misses for the same key through a
+// singleflight.Group, so a burst of line items referencing the same catalog
+// object only triggers one Square API call.
+type catalogCache struct {
+	mu          sync.Mutex
+	slotLabels  map[string]slotLabelCacheEntry
+	definitions map[string]definitionCacheEntry
+	group       singleflight.Group
+}
+
+func newCatalogCache() *catalogCache {
+	return &catalogCache{
+		slotLabels:  make(map[string]slotLabelCacheEntry),
+		definitions: make(map[string]definitionCacheEntry),
+	}
+}
+
+func (c *catalogCache) getSlotLabel(catalogObjectID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.slotLabels[catalogObjectID]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.slotLabel, true
+}
+
+func (c *catalogCache) setSlotLabel(catalogObjectID, slotLabel string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slotLabels[catalogObjectID] = slotLabelCacheEntry{slotLabel: slotLabel, expires: time.Now().Add(catalogCacheTTL)}
+}
+
+func (c *catalogCache) getDefinition(definitionID string) (model.SquareDefinitionResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.definitions[definitionID]
+	if !ok || time.Now().After(entry.expires) {
+		return model.SquareDefinitionResponse{}, false
+	}
+	return entry.definition, true
+}
+
+func (c *catalogCache) setDefinition(definitionID string, definition model.SquareDefinitionResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.definitions[definitionID] = definitionCacheEntry{definition: definition, expires: time.Now().Add(catalogCacheTTL)}
+}
+
+// invalidate drops every cached slot label and definition, forcing the next
+// lookup of each back out to Square.
+func (c *catalogCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slotLabels = make(map[string]slotLabelCacheEntry)
+	c.definitions = make(map[string]definitionCacheEntry)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/model"
+)
+
+func TestCatalogCacheSlotLabelRoundTrips(t *testing.T) {
+	c := newCatalogCache()
+
+	if _, ok := c.getSlotLabel("obj-1"); ok {
+		t.Fatal("expected a miss for an unset catalog object")
+	}
+
+	c.setSlotLabel("obj-1", "A1")
+	slotLabel, ok := c.getSlotLabel("obj-1")
+	if !ok || slotLabel != "A1" {
+		t.Fatalf("expected a hit of A1, got %q ok=%v", slotLabel, ok)
+	}
+}
+
+func TestCatalogCacheSlotLabelExpires(t *testing.T) {
+	c := newCatalogCache()
+	c.slotLabels["obj-1"] = slotLabelCacheEntry{slotLabel: "A1", expires: time.Now().Add(-time.Second)}
+
+	if _, ok := c.getSlotLabel("obj-1"); ok {
+		t.Fatal("expected an expired entry to be treated as a miss")
+	}
+}
+
+func TestCatalogCacheDefinitionRoundTrips(t *testing.T) {
+	c := newCatalogCache()
+
+	if _, ok := c.getDefinition("def-1"); ok {
+		t.Fatal("expected a miss for an unset definition")
+	}
+
+	var def model.SquareDefinitionResponse
+	def.Object.CustomAttributeDefinitionData.SelectionConfig.AllowedSelections = []struct {
+		UID  string `json:"uid"`
+		Name string `json:"name"`
+	}{{UID: "uid-1", Name: "A1"}}
+
+	c.setDefinition("def-1", def)
+	got, ok := c.getDefinition("def-1")
+	if !ok || len(got.Object.CustomAttributeDefinitionData.SelectionConfig.AllowedSelections) != 1 {
+		t.Fatalf("expected the cached definition back, got %+v ok=%v", got, ok)
+	}
+}
+
+func TestCatalogCacheInvalidateClearsBothMaps(t *testing.T) {
+	c := newCatalogCache()
+	c.setSlotLabel("obj-1", "A1")
+	c.setDefinition("def-1", model.SquareDefinitionResponse{})
+
+	c.invalidate()
+
+	if _, ok := c.getSlotLabel("obj-1"); ok {
+		t.Fatal("expected invalidate to clear cached slot labels")
+	}
+	if _, ok := c.getDefinition("def-1"); ok {
+		t.Fatal("expected invalidate to clear cached definitions")
+	}
+}
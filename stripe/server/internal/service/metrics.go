@@ -0,0 +1,25 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ordersProcessedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "vending_orders_processed_total",
+		Help: "Total number of Square orders processed, labeled by result.",
+	}, []string{"result"})
+
+	squareAPIDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "vending_square_api_duration_seconds",
+		Help: "Latency of calls made to the Square API, labeled by endpoint.",
+	}, []string{"endpoint"})
+)
+
+const (
+	resultSucceeded = "succeeded"
+	resultDuplicate = "duplicate"
+	resultIgnored   = "ignored"
+	resultError     = "error"
+)
@@ -1,8 +1,8 @@
 package service
 
 import (
-	"ieeeuottawa/vend-server/internal/model"
-	"ieeeuottawa/vend-server/internal/repository"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/model"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/repository"
 )
 
 type UserService interface {
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/logging"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/model"
+	"github.com/ieee-uottawa/vending-machine/stripe/server/internal/repository"
+
+	"github.com/ieee-uottawa/vending-machine/internal/dispenser"
+	"github.com/ieee-uottawa/vending-machine/internal/orderstore"
+)
+
+// DefaultWebhookMaxAge is used when NewVendingService is passed a
+// webhookMaxAge <= 0. Square redelivers an undelivered webhook for up to 72
+// hours, so this has to cover that whole retry horizon - anything shorter
+// drops legitimate retries of an event whose first delivery didn't finish
+// processing (a restart, a transient Square error), and since the order was
+// never dispensed, the paid order is silently lost.
+const DefaultWebhookMaxAge = 72 * time.Hour
+
+// VendingService holds the Square webhook and dispense business logic that
+// used to live directly on go-server's VendingMachine struct.
+type VendingService interface {
+	// VerifyWebhookSignature recomputes Square's HMAC-SHA256 signature for
+	// body and compares it against signatureHeader in constant time.
+	VerifyWebhookSignature(body []byte, signatureHeader string) bool
+
+	// ProcessSquareEvent dedupes and, for a completed payment, enqueues a
+	// dispense job for every slot the order paid for.
+	ProcessSquareEvent(ctx context.Context, payload model.SquareWebhookPayload) error
+
+	// Dispense enqueues a dispense job for slotLabel directly, bypassing
+	// Square. Used by the admin-authenticated dispense endpoint.
+	Dispense(ctx context.Context, slotLabel string) (dispenser.Job, error)
+
+	// Job looks up a single dispense job's current status.
+	Job(id string) (dispenser.Job, bool, error)
+
+	// Jobs lists every dispense job that's been recorded.
+	Jobs() ([]dispenser.Job, error)
+
+	// InvalidateCatalogCache drops every cached catalog object -> slot
+	// label mapping and attribute definition, forcing fresh Square lookups.
+	InvalidateCatalogCache()
+
+	// WarmCatalogCache pages through every catalog item and resolves its
+	// slot label up front, so the first webhook after a cold start doesn't
+	// pay the Square round-trip cost on the customer's time.
+	WarmCatalogCache(ctx context.Context) error
+}
+
+type vendingService struct {
+	slots               repository.SlotRepository
+	dispenser           *dispenser.Dispenser
+	orderStore          orderstore.OrderDedupeStore
+	catalogCache        *catalogCache
+	httpClient          *http.Client
+	squareToken         string
+	squareAPIBase       string
+	webhookSignatureKey string
+	notificationURL     string
+	webhookMaxAge       time.Duration
+}
+
+// NewVendingService wires a VendingService from its dependencies.
+// webhookMaxAge <= 0 falls back to DefaultWebhookMaxAge.
+func NewVendingService(
+	slots repository.SlotRepository,
+	jobDispenser *dispenser.Dispenser,
+	orderStore orderstore.OrderDedupeStore,
+	httpClient *http.Client,
+	squareToken, squareAPIBase, webhookSignatureKey, notificationURL string,
+	webhookMaxAge time.Duration,
+) VendingService {
+	if webhookMaxAge <= 0 {
+		webhookMaxAge = DefaultWebhookMaxAge
+	}
+	return &vendingService{
+		slots:               slots,
+		dispenser:           jobDispenser,
+		orderStore:          orderStore,
+		catalogCache:        newCatalogCache(),
+		httpClient:          httpClient,
+		squareToken:         squareToken,
+		squareAPIBase:       squareAPIBase,
+		webhookSignatureKey: webhookSignatureKey,
+		notificationURL:     notificationURL,
+		webhookMaxAge:       webhookMaxAge,
+	}
+}
+
+func (s *vendingService) VerifyWebhookSignature(body []byte, signatureHeader string) bool {
+	if s.webhookSignatureKey == "" || signatureHeader == "" {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.webhookSignatureKey))
+	mac.Write([]byte(s.notificationURL))
+	mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signatureHeader))
+}
+
+// isWebhookFresh reports whether a webhook's created_at timestamp (RFC 3339)
+// falls within s.webhookMaxAge of now. An empty or unparseable timestamp is
+// treated as stale, since we can't vouch for its age.
+func (s *vendingService) isWebhookFresh(createdAt string) bool {
+	ts, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return false
+	}
+	return time.Since(ts) <= s.webhookMaxAge
+}
+
+func (s *vendingService) Dispense(ctx context.Context, slotLabel string) (dispenser.Job, error) {
+	if !s.slots.HasSlot(slotLabel) {
+		return dispenser.Job{}, fmt.Errorf("unknown slot label: %s", slotLabel)
+	}
+	return s.dispenser.Enqueue("admin", slotLabel, logging.CorrelationID(ctx))
+}
+
+func (s *vendingService) Job(id string) (dispenser.Job, bool, error) {
+	return s.dispenser.Job(id)
+}
+
+func (s *vendingService) Jobs() ([]dispenser.Job, error) {
+	return s.dispenser.Jobs()
+}
+
+func (s *vendingService) InvalidateCatalogCache() {
+	s.catalogCache.invalidate()
+}
+
+func (s *vendingService) WarmCatalogCache(ctx context.Context) error {
+	listURL := fmt.Sprintf("%s/catalog/list?types=ITEM", s.squareAPIBase)
+	cursor := ""
+
+	for {
+		pageURL := listURL
+		if cursor != "" {
+			pageURL += "&cursor=" + cursor
+		}
+
+		resp, err := s.makeSquareAPIRequest(ctx, pageURL)
+		if err != nil {
+			return fmt.Errorf("failed to list catalog objects: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return fmt.Errorf("error listing catalog objects: %s", string(body))
+		}
+
+		var page model.SquareCatalogListResponse
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode catalog list response: %w", err)
+		}
+
+		for _, object := range page.Objects {
+			if _, err := s.getSlotLabelFromCatalogObject(ctx, object.ID); err != nil {
+				logging.FromContext(ctx, "vending_service").Warn("cache warmup: failed to resolve slot label",
+					"catalog_object_id", object.ID, "error", err)
+			}
+		}
+
+		if page.Cursor == "" {
+			return nil
+		}
+		cursor = page.Cursor
+	}
+}
+
+func (s *vendingService) ProcessSquareEvent(ctx context.Context, payload model.SquareWebhookPayload) error {
+	logger := logging.FromContext(ctx, "vending_service")
+
+	if payload.Type != "payment.updated" ||
+		payload.Data.Object.Payment.Status != "COMPLETED" ||
+		payload.Data.Object.Payment.OrderID == "" {
+		ordersProcessedTotal.WithLabelValues(resultIgnored).Inc()
+		return fmt.Errorf("ignoring non-payment event")
+	}
+
+	if !s.isWebhookFresh(payload.CreatedAt) {
+		ordersProcessedTotal.WithLabelValues(resultIgnored).Inc()
+		return fmt.Errorf("ignoring stale webhook event %s (created_at %s)", payload.ID, payload.CreatedAt)
+	}
+
+	orderID := payload.Data.Object.Payment.OrderID
+	logger = logger.With("order_id", orderID)
+	logger.Info("processing order")
+
+	// Fetch and decode the order before marking anything processed. There's
+	// no retry around makeSquareAPIRequest, so if we marked the event/order
+	// dedupe keys first and this call hit a transient Square 5xx or network
+	// blip, the order would be permanently marked done with nothing ever
+	// dispensed and no path to recovery - Square's retry would just see
+	// "already processed" and back off.
+	orderURL := fmt.Sprintf("%s/orders/%s", s.squareAPIBase, orderID)
+	resp, err := s.makeSquareAPIRequest(ctx, orderURL)
+	if err != nil {
+		ordersProcessedTotal.WithLabelValues(resultError).Inc()
+		return fmt.Errorf("failed to fetch order: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		ordersProcessedTotal.WithLabelValues(resultError).Inc()
+		return fmt.Errorf("error fetching order %s: %s", orderID, string(body))
+	}
+
+	var orderData model.SquareOrderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&orderData); err != nil {
+		ordersProcessedTotal.WithLabelValues(resultError).Inc()
+		return fmt.Errorf("failed to decode order response: %w", err)
+	}
+
+	// Only now that the order is in hand do we record it as processed, so a
+	// retried delivery of this same event/order is still honored if we never
+	// got this far last time.
+	if payload.ID != "" {
+		eventAlreadyProcessed, err := s.orderStore.MarkProcessed(ctx, "event:"+payload.ID)
+		if err != nil {
+			ordersProcessedTotal.WithLabelValues(resultError).Inc()
+			return fmt.Errorf("failed to check event dedupe store: %w", err)
+		}
+		if eventAlreadyProcessed {
+			logger.Info("ignoring duplicate webhook event", "event_id", payload.ID)
+			ordersProcessedTotal.WithLabelValues(resultDuplicate).Inc()
+			return fmt.Errorf("event already processed")
+		}
+	}
+
+	alreadyProcessed, err := s.orderStore.MarkProcessed(ctx, orderID)
+	if err != nil {
+		ordersProcessedTotal.WithLabelValues(resultError).Inc()
+		return fmt.Errorf("failed to check order dedupe store: %w", err)
+	}
+	if alreadyProcessed {
+		logger.Info("ignoring duplicate webhook for order")
+		ordersProcessedTotal.WithLabelValues(resultDuplicate).Inc()
+		return fmt.Errorf("order already processed")
+	}
+
+	for _, item := range orderData.Order.LineItems {
+		catalogObjectID := item.CatalogObjectID
+		if catalogObjectID == "" {
+			catalogObjectID = item.UID
+		}
+		if catalogObjectID == "" {
+			logger.Warn("no catalog object ID found for item")
+			continue
+		}
+
+		itemLogger := logger.With("catalog_object_id", catalogObjectID)
+
+		slotLabel, err := s.getSlotLabelFromCatalogObject(ctx, catalogObjectID)
+		if err != nil {
+			itemLogger.Warn("error getting slot label", "error", err)
+			continue
+		}
+
+		if slotLabel != "" {
+			// The dispenser serializes this onto the slot's own worker
+			// queue and persists it to the job store, so this returns as
+			// soon as the job is recorded rather than blocking on the
+			// relay pulse itself.
+			if _, err := s.dispenser.Enqueue(orderID, slotLabel, logging.CorrelationID(ctx)); err != nil {
+				itemLogger.Error("error enqueuing dispense", "slot", slotLabel, "error", err)
+			}
+		}
+	}
+
+	ordersProcessedTotal.WithLabelValues(resultSucceeded).Inc()
+	return nil
+}
+
+func (s *vendingService) makeSquareAPIRequest(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.squareToken)
+	req.Header.Set("Square-Version", "2025-07-16")
+	req.Header.Set("Content-Type", "application/json")
+
+	endpoint := s.squareAPIEndpointLabel(url)
+	start := time.Now()
+	resp, err := s.httpClient.Do(req)
+	squareAPIDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
+
+// squareAPIEndpointLabel collapses a Square API URL down to a low-
+// cardinality endpoint label (dropping path parameters like order and
+// catalog object IDs, and the query string) so the latency histogram
+// doesn't grow a series per order or catalog object.
+func (s *vendingService) squareAPIEndpointLabel(url string) string {
+	path := strings.TrimPrefix(url, s.squareAPIBase)
+	if idx := strings.IndexByte(path, '?'); idx != -1 {
+		path = path[:idx]
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/orders/"):
+		return "orders/:id"
+	case strings.HasPrefix(path, "/catalog/object/"):
+		return "catalog/object/:id"
+	case strings.HasPrefix(path, "/catalog/list"):
+		return "catalog/list"
+	default:
+		return "other"
+	}
+}
+
+// getSlotLabelFromCatalogObject resolves a Square catalog object to the
+// vending slot label it's configured to dispense from. Results are cached
+// for catalogCacheTTL, and concurrent lookups for the same catalogObjectID
+// are coalesced so a burst of line items for one item costs one Square
+// round-trip, not one per item.
+func (s *vendingService) getSlotLabelFromCatalogObject(ctx context.Context, catalogObjectID string) (string, error) {
+	if slotLabel, ok := s.catalogCache.getSlotLabel(catalogObjectID); ok {
+		return slotLabel, nil
+	}
+
+	result, err, _ := s.catalogCache.group.Do("object:"+catalogObjectID, func() (any, error) {
+		return s.fetchSlotLabelFromCatalogObject(ctx, catalogObjectID)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	slotLabel := result.(string)
+	s.catalogCache.setSlotLabel(catalogObjectID, slotLabel)
+	return slotLabel, nil
+}
+
+func (s *vendingService) fetchSlotLabelFromCatalogObject(ctx context.Context, catalogObjectID string) (string, error) {
+	objURL := fmt.Sprintf("%s/catalog/object/%s", s.squareAPIBase, catalogObjectID)
+	resp, err := s.makeSquareAPIRequest(ctx, objURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch catalog object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error fetching catalog object: status %d", resp.StatusCode)
+	}
+
+	var objData model.SquareCatalogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&objData); err != nil {
+		return "", fmt.Errorf("failed to decode catalog response: %w", err)
+	}
+
+	if len(objData.Object.CustomAttributeValues) == 0 {
+		return "", fmt.Errorf("no custom attributes found")
+	}
+
+	var firstAttr struct {
+		CustomAttributeDefinitionID string   `json:"custom_attribute_definition_id"`
+		SelectionUIDValues          []string `json:"selection_uid_values"`
+	}
+	for _, attr := range objData.Object.CustomAttributeValues {
+		firstAttr = attr
+		break
+	}
+
+	if len(firstAttr.SelectionUIDValues) == 0 {
+		return "", fmt.Errorf("no selection UID values found")
+	}
+
+	selectionUID := firstAttr.SelectionUIDValues[0]
+
+	defData, err := s.getAttributeDefinition(ctx, firstAttr.CustomAttributeDefinitionID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, selection := range defData.Object.CustomAttributeDefinitionData.SelectionConfig.AllowedSelections {
+		if selection.UID == selectionUID {
+			return selection.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("selection UID %s not found in definition", selectionUID)
+}
+
+// getAttributeDefinition resolves a Square custom attribute definition,
+// cached and singleflight-coalesced the same way getSlotLabelFromCatalogObject
+// is, since definitions are looked up at least as often as catalog objects.
+func (s *vendingService) getAttributeDefinition(ctx context.Context, definitionID string) (model.SquareDefinitionResponse, error) {
+	if definition, ok := s.catalogCache.getDefinition(definitionID); ok {
+		return definition, nil
+	}
+
+	result, err, _ := s.catalogCache.group.Do("definition:"+definitionID, func() (any, error) {
+		return s.fetchAttributeDefinition(ctx, definitionID)
+	})
+	if err != nil {
+		return model.SquareDefinitionResponse{}, err
+	}
+
+	definition := result.(model.SquareDefinitionResponse)
+	s.catalogCache.setDefinition(definitionID, definition)
+	return definition, nil
+}
+
+func (s *vendingService) fetchAttributeDefinition(ctx context.Context, definitionID string) (model.SquareDefinitionResponse, error) {
+	defURL := fmt.Sprintf("%s/catalog/object/%s", s.squareAPIBase, definitionID)
+	defResp, err := s.makeSquareAPIRequest(ctx, defURL)
+	if err != nil {
+		return model.SquareDefinitionResponse{}, fmt.Errorf("failed to fetch definition: %w", err)
+	}
+	defer defResp.Body.Close()
+
+	if defResp.StatusCode != http.StatusOK {
+		return model.SquareDefinitionResponse{}, fmt.Errorf("error fetching definition: status %d", defResp.StatusCode)
+	}
+
+	var defData model.SquareDefinitionResponse
+	if err := json.NewDecoder(defResp.Body).Decode(&defData); err != nil {
+		return model.SquareDefinitionResponse{}, fmt.Errorf("failed to decode definition response: %w", err)
+	}
+
+	return defData, nil
+}
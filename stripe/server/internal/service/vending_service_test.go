@@ -0,0 +1,55 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestIsWebhookFreshAcceptsRecentEvent(t *testing.T) {
+	svc := &vendingService{webhookMaxAge: DefaultWebhookMaxAge}
+	createdAt := time.Now().Add(-1 * time.Minute).Format(time.RFC3339)
+	if !svc.isWebhookFresh(createdAt) {
+		t.Fatal("expected event created 1 minute ago to be fresh")
+	}
+}
+
+func TestIsWebhookFreshRejectsStaleEvent(t *testing.T) {
+	svc := &vendingService{webhookMaxAge: DefaultWebhookMaxAge}
+	createdAt := time.Now().Add(-svc.webhookMaxAge - time.Minute).Format(time.RFC3339)
+	if svc.isWebhookFresh(createdAt) {
+		t.Fatal("expected event older than webhookMaxAge to be rejected as stale")
+	}
+}
+
+func TestIsWebhookFreshRejectsUnparseableTimestamp(t *testing.T) {
+	svc := &vendingService{webhookMaxAge: DefaultWebhookMaxAge}
+	if svc.isWebhookFresh("not-a-timestamp") {
+		t.Fatal("expected unparseable created_at to be rejected as stale")
+	}
+}
+
+func sign(notificationURL, key string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(notificationURL))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignatureRoundTrips(t *testing.T) {
+	svc := &vendingService{
+		webhookSignatureKey: "test-signature-key-do-not-use-in-prod",
+		notificationURL:     "https://vending.example.com/api/vending/webhook/square",
+	}
+	body := []byte(`{"type":"payment.updated"}`)
+	signature := sign(svc.notificationURL, svc.webhookSignatureKey, body)
+
+	if !svc.VerifyWebhookSignature(body, signature) {
+		t.Fatal("expected a freshly computed signature to verify")
+	}
+	if svc.VerifyWebhookSignature(body, "wrong-signature") {
+		t.Fatal("expected a mismatched signature to fail verification")
+	}
+}